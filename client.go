@@ -0,0 +1,184 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package sse
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// defaultMinRetryDelay is the initial reconnect delay used before the
+// server has sent a retry: field.
+const defaultMinRetryDelay = 1 * time.Second
+
+// defaultMaxRetryDelay caps the exponential backoff applied between
+// reconnect attempts.
+const defaultMaxRetryDelay = 30 * time.Second
+
+// Client subscribes to an SSE endpoint over HTTP, decoding the response
+// body with a Decoder and transparently reconnecting on network errors or
+// non-2xx responses.
+type Client struct {
+	// HTTPClient performs the underlying requests. Defaults to
+	// http.DefaultClient when nil; set it to control timeouts, TLS
+	// configuration, or transport behaviour.
+	HTTPClient *http.Client
+
+	// Headers are added to every request, e.g. for authentication.
+	Headers http.Header
+}
+
+// NewClient returns a Client ready to Subscribe.
+func NewClient() *Client {
+	return &Client{}
+}
+
+// Subscribe connects to url and calls handler for every event decoded from
+// the response body. On network error or a non-2xx response it reconnects
+// automatically, sending Last-Event-ID from the most recently decoded
+// event and honouring the server's most recent retry: field as the
+// reconnect delay, falling back to exponential backoff with jitter.
+// Subscribe blocks until ctx is canceled, returning ctx.Err().
+func (c *Client) Subscribe(ctx context.Context, url string, handler func(*Event)) error {
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	var lastEventID []byte
+	retryDelay := defaultMinRetryDelay
+	explicitRetry := false
+	attempt := 0
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		resp, err := c.connect(ctx, httpClient, url, lastEventID)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if !c.wait(ctx, nextDelay(attempt, retryDelay, explicitRetry)) {
+				return ctx.Err()
+			}
+			attempt++
+			continue
+		}
+
+		attempt = 0
+		lastEventID = c.consume(resp, lastEventID, &retryDelay, &explicitRetry, handler)
+		resp.Body.Close()
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !c.wait(ctx, nextDelay(attempt, retryDelay, explicitRetry)) {
+			return ctx.Err()
+		}
+		attempt++
+	}
+}
+
+// connect issues a single SSE request, returning an error for both
+// transport failures and non-2xx status codes so the caller can treat
+// them the same way when deciding to reconnect.
+func (c *Client) connect(ctx context.Context, httpClient *http.Client, url string, lastEventID []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range c.Headers {
+		req.Header[k] = v
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-cache")
+	if len(lastEventID) > 0 {
+		req.Header.Set("Last-Event-ID", string(lastEventID))
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		resp.Body.Close()
+		return nil, &statusError{code: resp.StatusCode}
+	}
+	return resp, nil
+}
+
+// consume decodes events from resp until the stream ends or errors,
+// invoking handler for each one and tracking the last event ID and retry
+// delay along the way.
+func (c *Client) consume(resp *http.Response, lastEventID []byte, retryDelay *time.Duration, explicitRetry *bool, handler func(*Event)) []byte {
+	dec := NewDecoder(resp.Body)
+	id := lastEventID
+	for {
+		var ev Event
+		if err := dec.Decode(&ev); err != nil {
+			return id
+		}
+		id = ev.ID
+		if ev.Retry > 0 {
+			*retryDelay = ev.Retry
+			*explicitRetry = true
+		}
+		handler(&ev)
+	}
+}
+
+// wait blocks for d, or until ctx is canceled, reporting whether the wait
+// completed without cancellation.
+func (c *Client) wait(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
+// nextDelay computes the delay before the next reconnect attempt. When the
+// server has sent an explicit retry: field, that value is honored as-is,
+// since it's the server's own choice of reconnect interval, not a fallback
+// to be grown or randomized. Otherwise it falls back to exponential backoff
+// with jitter off the default delay, so that many clients reconnecting at
+// once without server guidance don't do so in lockstep.
+func nextDelay(attempt int, retryDelay time.Duration, explicitRetry bool) time.Duration {
+	if explicitRetry {
+		return retryDelay
+	}
+	return backoff(attempt, retryDelay)
+}
+
+// backoff computes a delay doubled once per prior attempt up to
+// defaultMaxRetryDelay, then jittered so that many clients reconnecting at
+// once don't do so in lockstep.
+func backoff(attempt int, base time.Duration) time.Duration {
+	d := base
+	for i := 0; i < attempt && d < defaultMaxRetryDelay; i++ {
+		d *= 2
+	}
+	if d > defaultMaxRetryDelay {
+		d = defaultMaxRetryDelay
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d)/2+1))
+}
+
+// statusError reports a non-2xx HTTP response from an SSE endpoint.
+type statusError struct {
+	code int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("sse: unexpected status %d %s", e.code, http.StatusText(e.code))
+}
@@ -0,0 +1,116 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package sse
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNextDelay_ExplicitRetryHonoredAsIs(t *testing.T) {
+	const retry = 5 * time.Second
+	for attempt := 0; attempt < 3; attempt++ {
+		if got := nextDelay(attempt, retry, true); got != retry {
+			t.Errorf("nextDelay(%d, %s, true) = %s, want %s", attempt, retry, got, retry)
+		}
+	}
+}
+
+func TestNextDelay_FallsBackToBackoffWithoutExplicitRetry(t *testing.T) {
+	got := nextDelay(0, defaultMinRetryDelay, false)
+	if got < defaultMinRetryDelay/2 || got > defaultMinRetryDelay {
+		t.Errorf("nextDelay(0, %s, false) = %s, want within backoff(0, %s)'s range", defaultMinRetryDelay, got, defaultMinRetryDelay)
+	}
+}
+
+func TestClient_Subscribe_ReconnectsWithLastEventID(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&requests, 1)
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher := w.(http.Flusher)
+		if n == 1 {
+			// A short retry: tells Subscribe to reconnect almost
+			// immediately instead of waiting out the default delay.
+			fmt.Fprint(w, "id: 1\nretry: 1\ndata: hello\n\n")
+			flusher.Flush()
+			return
+		}
+		if got := r.Header.Get("Last-Event-ID"); got != "1" {
+			t.Errorf("reconnect Last-Event-ID = %q, want %q", got, "1")
+		}
+		fmt.Fprint(w, "id: 2\ndata: world\n\n")
+		flusher.Flush()
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var got []string
+	done := make(chan struct{})
+	go func() {
+		c := NewClient()
+		c.Subscribe(ctx, srv.URL, func(ev *Event) {
+			got = append(got, string(ev.Data))
+			if len(got) == 2 {
+				close(done)
+			}
+		})
+	}()
+
+	select {
+	case <-done:
+		cancel()
+	case <-time.After(5 * time.Second):
+		cancel()
+		t.Fatal("timed out waiting for reconnect to carry Last-Event-ID")
+	}
+}
+
+func TestClient_Subscribe_RetriesOnNon2xxInsteadOfFailing(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&requests, 1)
+		if n < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: finally\n\n")
+		w.(http.Flusher).Flush()
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		c := NewClient()
+		c.Subscribe(ctx, srv.URL, func(ev *Event) {
+			if string(ev.Data) == "finally" {
+				close(done)
+			}
+		})
+	}()
+
+	select {
+	case <-done:
+		cancel()
+	case <-time.After(5 * time.Second):
+		cancel()
+		t.Fatal("timed out waiting for Subscribe to retry past non-2xx responses")
+	}
+
+	if atomic.LoadInt32(&requests) < 3 {
+		t.Fatalf("requests = %d, want at least 3 (two failures then success)", requests)
+	}
+}
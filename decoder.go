@@ -0,0 +1,132 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package sse
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"time"
+)
+
+// utf8BOM is the byte order mark that some servers prepend to the very
+// first event of a stream.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// Decoder parses a raw byte stream into Events per the W3C EventSource
+// specification: it concatenates multiple data lines, strips a single
+// leading space after a field's colon, ignores comment lines starting
+// with ':', and remembers the last-seen id as the last event ID across
+// calls to Decode.
+type Decoder struct {
+	r           *EventStreamReader
+	lastEventID []byte
+	stripBOM    bool
+}
+
+// NewDecoder returns a Decoder that reads events from stream.
+func NewDecoder(stream io.Reader) *Decoder {
+	return &Decoder{
+		r:        NewEventStreamReader(stream),
+		stripBOM: true,
+	}
+}
+
+// LastEventID returns the most recently seen id field, per the spec's
+// "last event ID buffer". It is empty until an event carrying an id has
+// been decoded.
+func (d *Decoder) LastEventID() []byte {
+	return d.lastEventID
+}
+
+// Decode reads and parses the next event from the stream into ev. It
+// returns io.EOF once the stream is exhausted.
+func (d *Decoder) Decode(ev *Event) error {
+	block, err := d.r.ReadEvent()
+	if err != nil {
+		return err
+	}
+	if d.stripBOM {
+		block = bytes.TrimPrefix(block, utf8BOM)
+		d.stripBOM = false
+	}
+
+	*ev = Event{ID: d.lastEventID}
+
+	var data [][]byte
+	for _, line := range SplitLines(block) {
+		if len(line) == 0 || line[0] == ':' {
+			continue
+		}
+
+		field, value := parseField(line)
+		switch field {
+		case "id":
+			// value aliases the reader's internal buffer, which may be
+			// reused or resized on the next ReadEvent call, so it must
+			// be copied before it can outlive this Decode call.
+			d.lastEventID = append([]byte(nil), value...)
+			ev.ID = d.lastEventID
+		case "event":
+			// value aliases the reader's internal buffer, which may be
+			// reused or resized on the next ReadEvent call, so it must
+			// be copied before it can outlive this Decode call.
+			ev.Event = append([]byte(nil), value...)
+		case "data":
+			data = append(data, value)
+		case "retry":
+			if ms, err := strconv.Atoi(string(value)); err == nil {
+				ev.Retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+	if data != nil {
+		ev.Data = bytes.Join(data, []byte("\n"))
+	}
+
+	return nil
+}
+
+// parseField splits a single field line into its name and value,
+// stripping the separating colon and, per spec, a single leading space
+// in the value.
+func parseField(line []byte) (string, []byte) {
+	i := bytes.IndexByte(line, ':')
+	if i < 0 {
+		return string(line), nil
+	}
+	value := line[i+1:]
+	if len(value) > 0 && value[0] == ' ' {
+		value = value[1:]
+	}
+	return string(line[:i]), value
+}
+
+// SplitLines splits an event block into its individual field lines,
+// treating "\r\n", "\r" and "\n" all as line terminators per the
+// EventSource spec. It is exported so that callers writing their own
+// decoder on top of EventStreamReader can reuse the same line-splitting
+// rules Decoder does.
+func SplitLines(data []byte) [][]byte {
+	var lines [][]byte
+	start := 0
+	for i := 0; i < len(data); i++ {
+		switch data[i] {
+		case '\n':
+			lines = append(lines, data[start:i])
+			start = i + 1
+		case '\r':
+			lines = append(lines, data[start:i])
+			if i+1 < len(data) && data[i+1] == '\n' {
+				i++
+			}
+			start = i + 1
+		}
+	}
+	if start < len(data) {
+		lines = append(lines, data[start:])
+	}
+	return lines
+}
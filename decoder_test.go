@@ -0,0 +1,154 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package sse
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitLines(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []string
+	}{
+		{"lf", "a\nb\nc", []string{"a", "b", "c"}},
+		{"cr", "a\rb\rc", []string{"a", "b", "c"}},
+		{"crlf", "a\r\nb\r\nc", []string{"a", "b", "c"}},
+		{"mixed lf then cr", "a\nb\rc", []string{"a", "b", "c"}},
+		{"mixed cr then lf", "a\rb\nc", []string{"a", "b", "c"}},
+		{"mixed crlf then cr", "a\r\nb\rc", []string{"a", "b", "c"}},
+		{"mixed crlf then lf", "a\r\nb\nc", []string{"a", "b", "c"}},
+		{"mixed cr then crlf", "a\rb\r\nc", []string{"a", "b", "c"}},
+		{"mixed lf then crlf", "a\nb\r\nc", []string{"a", "b", "c"}},
+		{"trailing terminator dropped", "a\nb\n", []string{"a", "b"}},
+		{"no terminator", "a", []string{"a"}},
+		{"empty", "", nil},
+		{"blank lines preserved", "a\n\nb", []string{"a", "", "b"}},
+		{"cr cr blank line", "a\r\rb", []string{"a", "", "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SplitLines([]byte(tt.in))
+			var gotStrs []string
+			for _, l := range got {
+				gotStrs = append(gotStrs, string(l))
+			}
+			if !reflect.DeepEqual(gotStrs, tt.want) {
+				t.Errorf("SplitLines(%q) = %q, want %q", tt.in, gotStrs, tt.want)
+			}
+		})
+	}
+}
+
+// oneByteReader forces EventStreamReader to refill its buffer once per
+// byte, exercising double-newline detection and field parsing across many
+// small reads regardless of where a line terminator happens to fall.
+type oneByteReader struct {
+	data []byte
+}
+
+func (r *oneByteReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, r.data[:1])
+	r.data = r.data[1:]
+	return n, nil
+}
+
+func TestDecoder_HandlesLineTerminatorsAcrossBufferRefills(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{"crlf terminated", "id: 1\r\nevent: msg\r\ndata: line one\r\ndata: line two\r\n\r\n"},
+		{"cr terminated", "id: 1\revent: msg\rdata: line one\rdata: line two\r\r"},
+		{"lf terminated", "id: 1\nevent: msg\ndata: line one\ndata: line two\n\n"},
+		{"mixed terminators", "id: 1\r\nevent: msg\rdata: line one\ndata: line two\r\n\r\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dec := NewDecoder(&oneByteReader{data: []byte(tt.in)})
+			var ev Event
+			if err := dec.Decode(&ev); err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if string(ev.ID) != "1" {
+				t.Errorf("ID = %q, want %q", ev.ID, "1")
+			}
+			if string(ev.Event) != "msg" {
+				t.Errorf("Event = %q, want %q", ev.Event, "msg")
+			}
+			if !bytes.Equal(ev.Data, []byte("line one\nline two")) {
+				t.Errorf("Data = %q, want %q", ev.Data, "line one\nline two")
+			}
+		})
+	}
+}
+
+func TestDecoder_RetainedEventSurvivesNextDecode(t *testing.T) {
+	in := "event: first-type-name\ndata: a\n\nevent: second-type-totally-different\ndata: b\n\n"
+	dec := NewDecoder(strings.NewReader(in))
+
+	var first Event
+	if err := dec.Decode(&first); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	firstType := append([]byte(nil), first.Event...)
+
+	var second Event
+	if err := dec.Decode(&second); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+
+	if !bytes.Equal(first.Event, firstType) {
+		t.Fatalf("first event's Event field mutated by second Decode: got %q, want %q", first.Event, firstType)
+	}
+	if string(first.Event) != "first-type-name" {
+		t.Fatalf("first.Event = %q, want %q", first.Event, "first-type-name")
+	}
+	if string(second.Event) != "second-type-totally-different" {
+		t.Fatalf("second.Event = %q, want %q", second.Event, "second-type-totally-different")
+	}
+}
+
+func TestDecoder_MultipleEventsCommentsAndLastEventID(t *testing.T) {
+	in := "id: 1\ndata: first\n\n:this is a comment\ndata: second\n\nid: 2\ndata: third\n\n"
+	dec := NewDecoder(strings.NewReader(in))
+
+	var ev Event
+	if err := dec.Decode(&ev); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if string(ev.ID) != "1" || string(ev.Data) != "first" {
+		t.Fatalf("event 1 = %+v", ev)
+	}
+
+	if err := dec.Decode(&ev); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if string(ev.ID) != "1" || string(ev.Data) != "second" {
+		t.Fatalf("event 2 should inherit last event ID, got %+v", ev)
+	}
+	if dec.LastEventID() == nil || string(dec.LastEventID()) != "1" {
+		t.Fatalf("LastEventID() = %q, want %q", dec.LastEventID(), "1")
+	}
+
+	if err := dec.Decode(&ev); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if string(ev.ID) != "2" || string(ev.Data) != "third" {
+		t.Fatalf("event 3 = %+v", ev)
+	}
+
+	if _, err := dec.r.ReadEvent(); err != io.EOF {
+		t.Fatalf("expected io.EOF, got %v", err)
+	}
+}
@@ -12,20 +12,39 @@ import (
 	"time"
 )
 
-// Event holds all of the event source fields
+// Event holds all of the event source fields. Once returned from Decode,
+// an Event's fields never alias the Decoder's internal buffers and remain
+// valid indefinitely, even across later calls to Decode.
 type Event struct {
 	ID        []byte
 	Data      []byte
 	Event     []byte
-	Retry     []byte
+	Retry     time.Duration
 	timestamp time.Time
 }
 
+// startBufferSize is the initial capacity of a reader's internal buffer,
+// matching bufio.Scanner's own starting size.
+const startBufferSize = 4096
+
+// defaultMaxBufferSize is the buffer size limit used when
+// NewEventStreamReaderWithBufferSize is given 0, matching
+// bufio.MaxScanTokenSize.
+const defaultMaxBufferSize = 64 * 1024
+
 // EventStreamReader scans an io.Reader looking for EventStream messages.
+//
+// Unlike a bufio.Scanner based reader, it owns a single growable buffer
+// that it reads directly into and reuses across events: ReadEvent returns
+// a sub-slice of that buffer rather than a freshly allocated token, so
+// callers that need to retain an event must copy it before the next call.
 type EventStreamReader struct {
-	scanner *bufio.Scanner
-	buffer  []byte
-	idx     int
+	r       io.Reader
+	buf     []byte
+	start   int // offset of unconsumed data within buf
+	end     int // offset one past the valid data within buf
+	maxSize int
+	err     error // sticky error once the underlying reader is exhausted
 }
 
 // NewEventStreamReader creates an instance of EventStreamReader.
@@ -33,34 +52,21 @@ func NewEventStreamReader(eventStream io.Reader) *EventStreamReader {
 	return NewEventStreamReaderWithBufferSize(eventStream, 0)
 }
 
-// NewEventStreamReaderWithBufferSize creates an instance of EventStreamReader with custom buffer size.
+// NewEventStreamReaderWithBufferSize creates an instance of EventStreamReader
+// whose internal buffer will not grow past bufferSize bytes. A bufferSize of
+// 0 uses defaultMaxBufferSize.
 func NewEventStreamReaderWithBufferSize(eventStream io.Reader, bufferSize int) *EventStreamReader {
-	scanner := bufio.NewScanner(eventStream)
-	if bufferSize != 0 {
-		buf := make([]byte, 0, 4096)
-		scanner.Buffer(buf, bufferSize)
+	if bufferSize == 0 {
+		bufferSize = defaultMaxBufferSize
 	}
-	split := func(data []byte, atEOF bool) (int, []byte, error) {
-		if atEOF && len(data) == 0 {
-			return 0, nil, nil
-		}
-
-		// We have a full event payload to parse.
-		if i, nlen := containsDoubleNewline(data); i >= 0 {
-			return i + nlen, data[0:i], nil
-		}
-		// If we're at EOF, we have all of the data.
-		if atEOF {
-			return len(data), data, nil
-		}
-		// Request more data.
-		return 0, nil, nil
+	initial := startBufferSize
+	if initial > bufferSize {
+		initial = bufferSize
 	}
-	// Set the split function for the scanning operation.
-	scanner.Split(split)
-
 	return &EventStreamReader{
-		scanner: scanner,
+		r:       eventStream,
+		buf:     make([]byte, initial),
+		maxSize: bufferSize,
 	}
 }
 
@@ -101,17 +107,67 @@ func minPosInt(a, b int) int {
 	return a
 }
 
-// ReadEvent scans the EventStream for events.
+// ReadEvent scans the EventStream for events. The returned slice aliases
+// the reader's internal buffer and is only valid until the next call to
+// ReadEvent.
 func (e *EventStreamReader) ReadEvent() ([]byte, error) {
-	if e.scanner.Scan() {
-		event := e.scanner.Bytes()
-		return event, nil
+	for {
+		if i, nlen := containsDoubleNewline(e.buf[e.start:e.end]); i >= 0 {
+			event := e.buf[e.start : e.start+i]
+			e.start += i + nlen
+			return event, nil
+		}
+
+		if e.err != nil {
+			// Only a clean EOF flushes a trailing partial event; any other
+			// error is surfaced immediately without emitting the
+			// unterminated remainder.
+			if e.err == io.EOF && e.start < e.end {
+				event := e.buf[e.start:e.end]
+				e.start = e.end
+				return event, nil
+			}
+			if e.err == context.Canceled {
+				return nil, io.EOF
+			}
+			return nil, e.err
+		}
+
+		if err := e.fill(); err != nil {
+			e.err = err
+		}
+	}
+}
+
+// fill compacts the buffer, growing it if necessary, and reads more data
+// from the underlying reader into it.
+func (e *EventStreamReader) fill() error {
+	// Reclaim space already consumed by prior events.
+	if e.start > 0 {
+		e.end = copy(e.buf, e.buf[e.start:e.end])
+		e.start = 0
 	}
-	if err := e.scanner.Err(); err != nil {
-		if err == context.Canceled {
-			return nil, io.EOF
+
+	// Grow the buffer if it is full and there is still room to grow.
+	if e.end == len(e.buf) {
+		if len(e.buf) >= e.maxSize {
+			return bufio.ErrTooLong
+		}
+		newSize := len(e.buf) * 2
+		if newSize > e.maxSize {
+			newSize = e.maxSize
 		}
-		return nil, err
+		newBuf := make([]byte, newSize)
+		copy(newBuf, e.buf[:e.end])
+		e.buf = newBuf
+	}
+
+	n, err := e.r.Read(e.buf[e.end:])
+	e.end += n
+	if n > 0 && err == io.EOF {
+		// Data was returned alongside EOF; surface the data first and
+		// leave it to the next Read to report EOF again.
+		return nil
 	}
-	return nil, io.EOF
+	return err
 }
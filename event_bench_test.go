@@ -0,0 +1,108 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package sse
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+// benchStream repeats a single SSE event enough times to make allocation
+// differences between implementations easy to see.
+func benchStream(events int) string {
+	var b strings.Builder
+	for i := 0; i < events; i++ {
+		b.WriteString("id: 1\nevent: message\ndata: hello world\n\n")
+	}
+	return b.String()
+}
+
+// BenchmarkEventStreamReader_ReadEvent exercises the current buffer-reusing
+// EventStreamReader.
+func BenchmarkEventStreamReader_ReadEvent(b *testing.B) {
+	data := benchStream(b.N)
+	r := NewEventStreamReader(strings.NewReader(data))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for {
+		if _, err := r.ReadEvent(); err != nil {
+			break
+		}
+	}
+}
+
+// bufioScannerEventReader reimplements the pre-refactor bufio.Scanner based
+// reader so its allocation profile can be compared directly against
+// EventStreamReader.ReadEvent.
+func bufioScannerEventReader(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		if atEOF && len(data) == 0 {
+			return 0, nil, nil
+		}
+		if i, nlen := containsDoubleNewline(data); i >= 0 {
+			return i + nlen, data[0:i], nil
+		}
+		if atEOF {
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	})
+	return scanner
+}
+
+// BenchmarkBufioScanner_ReadEvent exercises the bufio.Scanner based
+// implementation that EventStreamReader used to be built on, for comparison.
+func BenchmarkBufioScanner_ReadEvent(b *testing.B) {
+	data := benchStream(b.N)
+	scanner := bufioScannerEventReader(strings.NewReader(data))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for scanner.Scan() {
+	}
+}
+
+func TestEventStreamReader_ReadEvent(t *testing.T) {
+	data := "id: 1\nevent: message\ndata: hello\n\nid: 2\ndata: world\n\n"
+	r := NewEventStreamReader(strings.NewReader(data))
+
+	first, err := r.ReadEvent()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(first, []byte("id: 1\nevent: message\ndata: hello")) {
+		t.Fatalf("unexpected first event: %q", first)
+	}
+
+	second, err := r.ReadEvent()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(second, []byte("id: 2\ndata: world")) {
+		t.Fatalf("unexpected second event: %q", second)
+	}
+
+	if _, err := r.ReadEvent(); err != io.EOF {
+		t.Fatalf("expected io.EOF, got %v", err)
+	}
+}
+
+func TestEventStreamReader_GrowsPastInitialBuffer(t *testing.T) {
+	big := strings.Repeat("x", startBufferSize*3)
+	data := "data: " + big + "\n\n"
+	r := NewEventStreamReaderWithBufferSize(strings.NewReader(data), startBufferSize*8)
+
+	event, err := r.ReadEvent()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "data: " + big
+	if string(event) != want {
+		t.Fatalf("event length = %d, want %d", len(event), len(want))
+	}
+}
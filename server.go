@@ -0,0 +1,214 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package sse
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// defaultQueueSize is the number of buffered events held per subscriber
+// before the oldest queued event is dropped to keep a slow client from
+// blocking the broker.
+const defaultQueueSize = 64
+
+// defaultHeartbeatInterval is how often a comment line is written to idle
+// connections to keep intermediate proxies from closing them.
+const defaultHeartbeatInterval = 15 * time.Second
+
+// defaultReplaySize is the number of past events a Stream retains so a
+// reconnecting client can replay everything it missed via Last-Event-ID.
+const defaultReplaySize = 256
+
+// Stream fans a sequence of published Events out to any number of
+// subscribed HTTP clients. It keeps a bounded ring buffer of recent
+// events so a client reconnecting with a Last-Event-ID header can replay
+// what it missed.
+type Stream struct {
+	// QueueSize bounds how many events are buffered per subscriber
+	// before the oldest is dropped to make room. Defaults to
+	// defaultQueueSize when zero.
+	QueueSize int
+	// HeartbeatInterval controls how often a ":" comment line is sent
+	// to idle connections. Defaults to defaultHeartbeatInterval when
+	// zero.
+	HeartbeatInterval time.Duration
+
+	mu          sync.Mutex
+	nextID      uint64
+	subscribers map[*subscriber]struct{}
+	replay      []Event
+	replayCap   int
+}
+
+// NewStream returns a Stream ready to Publish to and serve via Handler.
+func NewStream() *Stream {
+	return &Stream{
+		subscribers: make(map[*subscriber]struct{}),
+		replayCap:   defaultReplaySize,
+	}
+}
+
+// subscriber is one connected client's outgoing event queue.
+type subscriber struct {
+	queue chan Event
+}
+
+// send enqueues ev for delivery, dropping the oldest queued event first
+// if the queue is full so a slow client falls behind rather than
+// blocking the publisher.
+func (sub *subscriber) send(ev Event) {
+	select {
+	case sub.queue <- ev:
+		return
+	default:
+	}
+	select {
+	case <-sub.queue:
+	default:
+	}
+	select {
+	case sub.queue <- ev:
+	default:
+	}
+}
+
+// Publish assigns ev the next monotonically increasing ID if it does not
+// already have one, records it for replay, and delivers it to every
+// currently subscribed client.
+func (s *Stream) Publish(ev Event) {
+	s.mu.Lock()
+	if len(ev.ID) == 0 {
+		s.nextID++
+		ev.ID = []byte(fmt.Sprintf("%d", s.nextID))
+	}
+	s.replay = append(s.replay, ev)
+	if len(s.replay) > s.replayCap {
+		s.replay = s.replay[len(s.replay)-s.replayCap:]
+	}
+	subs := make([]*subscriber, 0, len(s.subscribers))
+	for sub := range s.subscribers {
+		subs = append(subs, sub)
+	}
+	s.mu.Unlock()
+
+	for _, sub := range subs {
+		sub.send(ev)
+	}
+}
+
+// replaySince returns the buffered events after the one with the given
+// ID, or every buffered event if lastEventID is empty or not found. The
+// caller must hold s.mu.
+func (s *Stream) replaySince(lastEventID []byte) []Event {
+	if len(lastEventID) > 0 {
+		for i, ev := range s.replay {
+			if bytes.Equal(ev.ID, lastEventID) {
+				return append([]Event(nil), s.replay[i+1:]...)
+			}
+		}
+	}
+	return append([]Event(nil), s.replay...)
+}
+
+// Handler returns an http.HandlerFunc that serves the stream to one
+// client per request. It replays any events since the client's
+// Last-Event-ID header, then writes newly published events as they
+// arrive and periodic comment heartbeats while idle, flushing after
+// every write.
+func (s *Stream) Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+
+		header := w.Header()
+		header.Set("Content-Type", "text/event-stream")
+		header.Set("Cache-Control", "no-cache")
+		header.Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+
+		queueSize := s.QueueSize
+		if queueSize <= 0 {
+			queueSize = defaultQueueSize
+		}
+		sub := &subscriber{queue: make(chan Event, queueSize)}
+
+		s.mu.Lock()
+		backlog := s.replaySince([]byte(r.Header.Get("Last-Event-ID")))
+		s.subscribers[sub] = struct{}{}
+		s.mu.Unlock()
+
+		defer func() {
+			s.mu.Lock()
+			delete(s.subscribers, sub)
+			s.mu.Unlock()
+		}()
+
+		for _, ev := range backlog {
+			if !writeEvent(w, ev) {
+				return
+			}
+			flusher.Flush()
+		}
+		flusher.Flush()
+
+		interval := s.HeartbeatInterval
+		if interval <= 0 {
+			interval = defaultHeartbeatInterval
+		}
+		heartbeat := time.NewTicker(interval)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case ev := <-sub.queue:
+				if !writeEvent(w, ev) {
+					return
+				}
+				flusher.Flush()
+				heartbeat.Reset(interval)
+			case <-heartbeat.C:
+				if _, err := io.WriteString(w, ":\n\n"); err != nil {
+					return
+				}
+				flusher.Flush()
+			}
+		}
+	}
+}
+
+// writeEvent writes ev to w in SSE wire format, splitting Data on any of
+// "\r", "\n" or "\r\n" into multiple data: lines (matching the line
+// terminators SplitLines treats as significant on read-back), and reports
+// whether the write succeeded.
+func writeEvent(w io.Writer, ev Event) bool {
+	var buf bytes.Buffer
+	if len(ev.ID) > 0 {
+		fmt.Fprintf(&buf, "id: %s\n", ev.ID)
+	}
+	if len(ev.Event) > 0 {
+		fmt.Fprintf(&buf, "event: %s\n", ev.Event)
+	}
+	if ev.Retry > 0 {
+		fmt.Fprintf(&buf, "retry: %d\n", ev.Retry/time.Millisecond)
+	}
+	for _, line := range SplitLines(ev.Data) {
+		buf.WriteString("data: ")
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	buf.WriteByte('\n')
+	_, err := w.Write(buf.Bytes())
+	return err == nil
+}
@@ -0,0 +1,101 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package sse
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWriteEvent(t *testing.T) {
+	var buf bytes.Buffer
+	ev := Event{ID: []byte("1"), Event: []byte("greeting"), Data: []byte("hello\nworld")}
+	if !writeEvent(&buf, ev) {
+		t.Fatal("writeEvent reported failure")
+	}
+	want := "id: 1\nevent: greeting\ndata: hello\ndata: world\n\n"
+	if buf.String() != want {
+		t.Fatalf("writeEvent output = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestWriteEvent_CROnlyDataRoundTrips(t *testing.T) {
+	var buf bytes.Buffer
+	ev := Event{Data: []byte("foo\rbar")}
+	if !writeEvent(&buf, ev) {
+		t.Fatal("writeEvent reported failure")
+	}
+
+	dec := NewDecoder(&buf)
+	var got Event
+	if err := dec.Decode(&got); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if !bytes.Equal(got.Data, []byte("foo\nbar")) {
+		t.Fatalf("Data = %q, want %q", got.Data, "foo\nbar")
+	}
+}
+
+func TestStream_PublishAssignsSequentialIDs(t *testing.T) {
+	s := NewStream()
+	s.Publish(Event{Data: []byte("one")})
+	s.Publish(Event{Data: []byte("two")})
+	s.Publish(Event{ID: []byte("custom"), Data: []byte("three")})
+
+	if got := string(s.replay[0].ID); got != "1" {
+		t.Errorf("first event ID = %q, want %q", got, "1")
+	}
+	if got := string(s.replay[1].ID); got != "2" {
+		t.Errorf("second event ID = %q, want %q", got, "2")
+	}
+	if got := string(s.replay[2].ID); got != "custom" {
+		t.Errorf("third event ID = %q, want %q", got, "custom")
+	}
+}
+
+func TestStream_HandlerReplaysBacklogAndLastEventID(t *testing.T) {
+	s := NewStream()
+	s.Publish(Event{Data: []byte("one")})
+	s.Publish(Event{Data: []byte("two")})
+	s.Publish(Event{Data: []byte("three")})
+
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatalf("NewRequestWithContext: %v", err)
+	}
+	req.Header.Set("Last-Event-ID", "1")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	defer resp.Body.Close()
+
+	dec := NewDecoder(resp.Body)
+
+	var ev Event
+	if err := dec.Decode(&ev); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if string(ev.Data) != "two" {
+		t.Fatalf("first replayed event = %q, want %q", ev.Data, "two")
+	}
+
+	if err := dec.Decode(&ev); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if string(ev.Data) != "three" {
+		t.Fatalf("second replayed event = %q, want %q", ev.Data, "three")
+	}
+}